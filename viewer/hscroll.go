@@ -0,0 +1,25 @@
+package viewer
+
+import "github.com/charmbracelet/x/ansi"
+
+// clipLeft returns line with the first xOffset display cells removed,
+// preserving any SGR styling that spans the cut. It's a no-op for
+// non-positive xOffset.
+func clipLeft(line string, xOffset int) string {
+	if xOffset <= 0 {
+		return line
+	}
+	return ansi.Cut(line, xOffset, ansi.StringWidth(line))
+}
+
+// maxLineWidth returns the display width, in cells, of the widest line in
+// lines.
+func maxLineWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w > width {
+			width = w
+		}
+	}
+	return width
+}
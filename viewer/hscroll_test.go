@@ -0,0 +1,61 @@
+package viewer
+
+import "testing"
+
+func TestClipLeft(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		xOffset int
+		want    string
+	}{
+		{name: "zero offset is a no-op", line: "hello world", xOffset: 0, want: "hello world"},
+		{name: "negative offset is a no-op", line: "hello world", xOffset: -3, want: "hello world"},
+		{name: "clips from the left", line: "hello world", xOffset: 6, want: "world"},
+		{name: "offset past the end clips everything", line: "hi", xOffset: 10, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clipLeft(tt.line, tt.xOffset); got != tt.want {
+				t.Errorf("clipLeft(%q, %d) = %q, want %q", tt.line, tt.xOffset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxLineWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  int
+	}{
+		{name: "empty input", lines: nil, want: 0},
+		{name: "single line", lines: []string{"abc"}, want: 3},
+		{name: "widest line wins", lines: []string{"a", "abcdef", "abc"}, want: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxLineWidth(tt.lines); got != tt.want {
+				t.Errorf("maxLineWidth(%v) = %d, want %d", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineRightClampsToMaxXOffset(t *testing.T) {
+	m := ViewportModel{Width: 5, Height: 3}
+	m.SetContent("short\nthis line is much wider than five cells")
+
+	m.LineRight(1000)
+
+	if got, want := m.XOffset, m.maxXOffset(); got != want {
+		t.Errorf("XOffset = %d after scrolling far right, want clamped to maxXOffset() = %d", got, want)
+	}
+
+	m.GotoLeftmost()
+	if m.XOffset != 0 {
+		t.Errorf("XOffset = %d after GotoLeftmost, want 0", m.XOffset)
+	}
+}
@@ -0,0 +1,78 @@
+package viewer
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the keybindings used by ViewportModel's default Update
+// loop. Embed it in your own model (or swap it out entirely) to remap or
+// disable pager keys without forking the viewport itself.
+type KeyMap struct {
+	PageDown     key.Binding
+	PageUp       key.Binding
+	HalfPageUp   key.Binding
+	HalfPageDown key.Binding
+	Down         key.Binding
+	Up           key.Binding
+
+	GotoTop    key.Binding
+	GotoBottom key.Binding
+
+	// Left and Right scroll the viewport horizontally. They only take
+	// effect when SoftWrap is disabled.
+	Left  key.Binding
+	Right key.Binding
+
+	// Search is not handled by Update: entering a pattern needs a text
+	// input this package doesn't own. Callers that embed a prompt should
+	// check key.Matches(msg, KeyMap.Search) themselves and call SetSearch
+	// once the user finishes typing. NextMatch and PrevMatch need no text
+	// entry and are handled directly by Update.
+	Search    key.Binding
+	NextMatch key.Binding
+	PrevMatch key.Binding
+}
+
+// DefaultKeyMap returns a KeyMap with the same bindings ViewportModel has
+// always used for paging, plus sqlite-tui's new goto/search bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", spacebar, "f"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup", "b"),
+		),
+		HalfPageUp: key.NewBinding(
+			key.WithKeys("u", "ctrl+u"),
+		),
+		HalfPageDown: key.NewBinding(
+			key.WithKeys("d", "ctrl+d"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+		),
+		GotoTop: key.NewBinding(
+			key.WithKeys("g", "home"),
+		),
+		GotoBottom: key.NewBinding(
+			key.WithKeys("G", "end"),
+		),
+		Left: key.NewBinding(
+			key.WithKeys("h", "left"),
+		),
+		Right: key.NewBinding(
+			key.WithKeys("l", "right"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+		),
+		NextMatch: key.NewBinding(
+			key.WithKeys("n"),
+		),
+		PrevMatch: key.NewBinding(
+			key.WithKeys("N"),
+		),
+	}
+}
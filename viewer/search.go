@@ -0,0 +1,195 @@
+package viewer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ErrSearchUnsupportedWithSoftWrap is returned by SetSearch when SoftWrap is
+// enabled. Matches are indexed against raw m.lines, which have no stable
+// mapping into wrapped-line space, so jump-to-match and highlighting can't
+// be made to work correctly while wrapping is on.
+var ErrSearchUnsupportedWithSoftWrap = errors.New("viewer: search is not supported while SoftWrap is enabled")
+
+// match records the location of a single search hit: the index into
+// m.lines it was found on, and its start/end column (a byte offset into the
+// line's plain, ANSI-stripped text).
+type match struct {
+	line     int
+	startCol int
+	endCol   int
+}
+
+// SetSearch indexes every occurrence of pattern in the viewport's content
+// and jumps to the first match. Pass an empty pattern to clear the search.
+// If regex is true, pattern is compiled as a regular expression and an
+// invalid pattern is returned as an error; caseSensitive is ignored for
+// regex patterns other than the implicit (?i) this method adds when false.
+func (m *ViewportModel) SetSearch(pattern string, caseSensitive bool, regex bool) error {
+	if m.SoftWrap {
+		return ErrSearchUnsupportedWithSoftWrap
+	}
+
+	if pattern == "" {
+		m.searchPattern = ""
+		m.searchRe = nil
+		m.matches = nil
+		m.matchIdx = -1
+		return nil
+	}
+
+	var re *regexp.Regexp
+	if regex {
+		expr := pattern
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		var err error
+		re, err = regexp.Compile(expr)
+		if err != nil {
+			// Leave the previous search (pattern, flags, compiled regex,
+			// matches) intact so a later SetContent doesn't rebuild against
+			// a half-updated, potentially nil m.searchRe.
+			return err
+		}
+	}
+
+	m.searchPattern = pattern
+	m.searchCaseSensitive = caseSensitive
+	m.searchRegex = regex
+	m.searchRe = re
+
+	m.buildMatches()
+	if len(m.matches) > 0 {
+		m.gotoMatch(0)
+	}
+
+	return nil
+}
+
+// ClearSearch discards the active search and its match index.
+func (m *ViewportModel) ClearSearch() {
+	m.searchPattern = ""
+	m.searchRe = nil
+	m.matches = nil
+	m.matchIdx = -1
+}
+
+// NextMatch jumps to the next search match, wrapping past the last one. It's
+// a no-op if SoftWrap is enabled; see ErrSearchUnsupportedWithSoftWrap.
+func (m *ViewportModel) NextMatch() {
+	if len(m.matches) == 0 || m.SoftWrap {
+		return
+	}
+	m.matchIdx = (m.matchIdx + 1) % len(m.matches)
+	m.gotoMatch(m.matchIdx)
+}
+
+// PrevMatch jumps to the previous search match, wrapping past the first one.
+// It's a no-op if SoftWrap is enabled; see ErrSearchUnsupportedWithSoftWrap.
+func (m *ViewportModel) PrevMatch() {
+	if len(m.matches) == 0 || m.SoftWrap {
+		return
+	}
+	m.matchIdx--
+	if m.matchIdx < 0 {
+		m.matchIdx = len(m.matches) - 1
+	}
+	m.gotoMatch(m.matchIdx)
+}
+
+// gotoMatch scrolls so the given match index lands roughly in the vertical
+// middle of the viewport. match.line indexes raw m.lines, so callers must
+// keep SoftWrap disabled (SetSearch/NextMatch/PrevMatch all enforce this);
+// otherwise YOffset, which is in renderedLines() space, would be set from
+// the wrong coordinate space.
+func (m *ViewportModel) gotoMatch(idx int) {
+	if idx < 0 || idx >= len(m.matches) {
+		return
+	}
+
+	target := m.matches[idx].line - m.Height/2
+	m.YOffset = clamp(target, 0, max(0, len(m.renderedLines())-1-m.Height))
+}
+
+// buildMatches rebuilds the match index from the current search pattern
+// against m.lines. It's called whenever the content or the search changes.
+// It resets matchIdx to the first match but leaves YOffset alone; SetSearch
+// is what jumps to that match.
+func (m *ViewportModel) buildMatches() {
+	m.matches = nil
+
+	if m.searchPattern == "" {
+		m.matchIdx = -1
+		return
+	}
+
+	for i, line := range m.lines {
+		plain := ansi.Strip(line)
+
+		if m.searchRegex {
+			for _, loc := range m.searchRe.FindAllStringIndex(plain, -1) {
+				m.matches = append(m.matches, match{line: i, startCol: loc[0], endCol: loc[1]})
+			}
+			continue
+		}
+
+		haystack, needle := plain, m.searchPattern
+		if !m.searchCaseSensitive {
+			haystack = strings.ToLower(haystack)
+			needle = strings.ToLower(needle)
+		}
+
+		for start := 0; ; {
+			idx := strings.Index(haystack[start:], needle)
+			if idx < 0 {
+				break
+			}
+			from := start + idx
+			to := from + len(needle)
+			m.matches = append(m.matches, match{line: i, startCol: from, endCol: to})
+			start = to
+		}
+	}
+
+	m.matchIdx = -1
+	if len(m.matches) > 0 {
+		m.matchIdx = 0
+	}
+}
+
+// highlightLine wraps the substrings of line that fall within an indexed
+// match in MatchStyle's SGR sequence, using x/ansi so styling already
+// present in line survives the cut. absLine is line's index into m.lines.
+//
+// match.startCol/endCol are byte offsets into the plain, ANSI-stripped
+// text, but ansi.Cut works in display cells, so each match is converted via
+// ansi.ByteToGraphemeRange (against the same stripped text the match was
+// found in) before it's used to cut line.
+func (m ViewportModel) highlightLine(absLine int, line string) string {
+	plain := ansi.Strip(line)
+	width := ansi.StringWidth(line)
+
+	var out strings.Builder
+	cursor := 0
+	for _, mt := range m.matches {
+		if mt.line != absLine {
+			continue
+		}
+		startCell, endCell := ansi.ByteToGraphemeRange(plain, mt.startCol, mt.endCol)
+		if startCell < cursor {
+			continue
+		}
+		out.WriteString(ansi.Cut(line, cursor, startCell))
+		out.WriteString(m.MatchStyle.Render(ansi.Cut(line, startCell, endCell)))
+		cursor = endCell
+	}
+	if cursor < width {
+		out.WriteString(ansi.Cut(line, cursor, width))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,133 @@
+package viewer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSetSearchInvalidRegexPreservesPriorState(t *testing.T) {
+	var m ViewportModel
+	m.SetContent("one needle here\ntwo needle here")
+
+	if err := m.SetSearch("needle", false, false); err != nil {
+		t.Fatalf("SetSearch(valid) returned error: %v", err)
+	}
+	wantMatches := len(m.matches)
+
+	if err := m.SetSearch("[invalid(", false, true); err == nil {
+		t.Fatalf("SetSearch(invalid regex) returned nil error, want a compile error")
+	}
+
+	if m.searchRegex {
+		t.Errorf("searchRegex = true after failed SetSearch, want the prior (non-regex) state preserved")
+	}
+	if len(m.matches) != wantMatches {
+		t.Errorf("matches = %d after failed SetSearch, want the prior %d preserved", len(m.matches), wantMatches)
+	}
+
+	// The real regression: a later SetContent (which rebuilds the match
+	// index, as AppendContent/DeleteTopContent do for streaming query
+	// results) must not panic by calling FindAllStringIndex on a nil
+	// compiled regex.
+	m.SetContent("three needle here")
+}
+
+func TestSetSearchEmptyPatternClears(t *testing.T) {
+	var m ViewportModel
+	m.SetContent("needle needle needle")
+
+	if err := m.SetSearch("needle", false, false); err != nil {
+		t.Fatalf("SetSearch returned error: %v", err)
+	}
+	if len(m.matches) == 0 {
+		t.Fatalf("expected matches after SetSearch(\"needle\")")
+	}
+
+	if err := m.SetSearch("", false, false); err != nil {
+		t.Fatalf("SetSearch(\"\") returned error: %v", err)
+	}
+	if len(m.matches) != 0 {
+		t.Errorf("matches = %d after SetSearch(\"\"), want 0", len(m.matches))
+	}
+}
+
+func TestHighlightLineUsesUnclippedColumns(t *testing.T) {
+	m := ViewportModel{Width: 80, Height: 3, XOffset: 5}
+	m.SetContent("prefixneedlesuffix")
+	if err := m.SetSearch("needle", true, false); err != nil {
+		t.Fatalf("SetSearch returned error: %v", err)
+	}
+
+	lines := m.rawVisibleLines()
+	highlighted := m.highlightLine(0, lines[0])
+	clipped := clipLinesLeft([]string{highlighted}, m.XOffset)[0]
+
+	plain := stripMatchStyle(clipped)
+	if plain != "xneedlesuffix" {
+		t.Fatalf("clipped+highlighted line = %q, want the XOffset=5 clip of the original line (%q)", plain, "xneedlesuffix")
+	}
+}
+
+// TestHighlightLineNonASCII guards against match.startCol/endCol (byte
+// offsets into the ANSI-stripped plain text) being fed straight into
+// ansi.Cut (which expects display-cell offsets): a multi-byte rune before
+// the match used to shift the cut past the start of the match.
+func TestHighlightLineNonASCII(t *testing.T) {
+	content := "café bar café baz"
+	m := ViewportModel{Width: 80, Height: 3}
+	m.MatchStyle = lipgloss.NewStyle().Bold(true)
+	m.SetContent(content)
+
+	if err := m.SetSearch("baz", true, false); err != nil {
+		t.Fatalf("SetSearch returned error: %v", err)
+	}
+
+	highlighted := m.highlightLine(0, content)
+
+	if plain := stripMatchStyle(highlighted); plain != content {
+		t.Fatalf("highlightLine changed the visible text: got %q, want %q", plain, content)
+	}
+
+	wantStyled := m.MatchStyle.Render("baz")
+	if !strings.Contains(highlighted, wantStyled) {
+		t.Fatalf("highlightLine did not wrap the whole match %q; got %q", "baz", highlighted)
+	}
+}
+
+// TestSearchDisabledUnderSoftWrap documents and locks in the SoftWrap guard:
+// match.line indexes raw m.lines, which has no stable mapping into
+// wrapped-line space, so SetSearch refuses rather than silently scrolling
+// to (or highlighting) the wrong place.
+func TestSearchDisabledUnderSoftWrap(t *testing.T) {
+	m := ViewportModel{Width: 10, Height: 3, SoftWrap: true}
+	m.SetContent("needle here\nanother line")
+
+	err := m.SetSearch("needle", true, false)
+	if !errors.Is(err, ErrSearchUnsupportedWithSoftWrap) {
+		t.Fatalf("SetSearch with SoftWrap=true returned %v, want ErrSearchUnsupportedWithSoftWrap", err)
+	}
+	if len(m.matches) != 0 {
+		t.Fatalf("matches = %d after a refused SetSearch, want 0", len(m.matches))
+	}
+}
+
+// stripMatchStyle removes the SGR sequences highlightLine wraps matches in,
+// for comparing just the visible text in tests.
+func stripMatchStyle(s string) string {
+	var out []rune
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape && r == 'm':
+			inEscape = false
+		case !inEscape:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
@@ -2,9 +2,12 @@ package viewer
 
 import (
 	"math"
+	"regexp"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 const (
@@ -20,6 +23,11 @@ type ViewportModel struct {
 	// YOffset is the vertical scroll position.
 	YOffset int
 
+	// XOffset is the horizontal scroll position, in display cells. It's
+	// only consulted when SoftWrap is disabled; soft-wrapped content has no
+	// need to scroll horizontally.
+	XOffset int
+
 	// YPosition is the position of the viewport in relation to the terminal
 	// window. It's used in high performance rendering.
 	YPosition int
@@ -34,7 +42,49 @@ type ViewportModel struct {
 	// which is usually via the alternate screen buffer.
 	HighPerformanceRendering bool
 
+	// KeyMap defines the keybindings the default Update loop consults. It's
+	// exported so callers embedding ViewportModel in a larger program (e.g.
+	// a SQL editor with its own keybindings) can remap or disable pager keys
+	// without forking this file.
+	KeyMap KeyMap
+
+	// SoftWrap, when true, soft-wraps lines wider than Width instead of
+	// letting them truncate and scroll horizontally. Navigation (scroll
+	// percent, at-bottom checks, visible lines) is computed against the
+	// wrapped content rather than the raw lines passed to SetContent.
+	SoftWrap bool
+
+	// MatchStyle styles the substrings found by the active search. See
+	// SetSearch.
+	MatchStyle lipgloss.Style
+
 	lines []string
+
+	// search state. See search.go.
+	matches             []match
+	matchIdx            int
+	searchPattern       string
+	searchCaseSensitive bool
+	searchRegex         bool
+	searchRe            *regexp.Regexp
+
+	// wrappedLines and wrapWidth cache the result of wrapping lines against
+	// Width. The cache is invalidated on content changes and recomputed
+	// lazily whenever Width no longer matches wrapWidth.
+	wrappedLines []string
+	wrapWidth    int
+}
+
+// NewViewportModel returns a ViewportModel with its KeyMap populated from
+// DefaultKeyMap. The zero value of ViewportModel is still valid to use
+// directly, but its KeyMap will be empty and Update will not respond to any
+// keys until one is assigned.
+func NewViewportModel(width, height int) ViewportModel {
+	return ViewportModel{
+		Width:  width,
+		Height: height,
+		KeyMap: DefaultKeyMap(),
+	}
 }
 
 // AtTop returns whether or not the viewport is in the very top position.
@@ -44,49 +94,197 @@ func (m ViewportModel) AtTop() bool {
 
 // AtBottom returns whether or not the viewport is at or past the very bottom
 // position.
-func (m ViewportModel) AtBottom() bool {
-	return m.YOffset >= len(m.lines)-1-m.Height
+func (m *ViewportModel) AtBottom() bool {
+	lines := m.renderedLines()
+	return m.YOffset >= len(lines)-1-m.Height
 }
 
 // PastBottom returns whether or not the viewport is scrolled beyond the last
 // line. This can happen when adjusting the viewport height.
-func (m ViewportModel) PastBottom() bool {
-	return m.YOffset > len(m.lines)-1-m.Height
+func (m *ViewportModel) PastBottom() bool {
+	lines := m.renderedLines()
+	return m.YOffset > len(lines)-1-m.Height
 }
 
 // ScrollPercent returns the amount scrolled as a float between 0 and 1.
-func (m ViewportModel) ScrollPercent() float64 {
-	if m.Height >= len(m.lines) {
+func (m *ViewportModel) ScrollPercent() float64 {
+	lines := m.renderedLines()
+	if m.Height >= len(lines) {
 		return 1.0
 	}
 	y := float64(m.YOffset)
 	h := float64(m.Height)
-	t := float64(len(m.lines) - 1)
+	t := float64(len(lines) - 1)
 	v := y / (t - h)
 	return math.Max(0.0, math.Min(1.0, v))
 }
 
+// renderedLines returns the lines navigation math should operate on: the
+// wrapped cache when SoftWrap is enabled (recomputing it if Width has
+// changed since it was last built), or the raw lines otherwise.
+func (m *ViewportModel) renderedLines() []string {
+	if !m.SoftWrap {
+		return m.lines
+	}
+	if m.wrappedLines == nil || m.wrapWidth != m.Width {
+		m.wrappedLines = wrap(m.lines, m.Width)
+		m.wrapWidth = m.Width
+	}
+	return m.wrappedLines
+}
+
 // SetContent set the pager's text content. For high performance rendering the
 // Sync command should also be called.
 func (m *ViewportModel) SetContent(s string) {
 	s = strings.Replace(s, "\r\n", "\n", -1) // normalize line endings
 	m.lines = strings.Split(s, "\n")
+	m.wrappedLines = nil
+	m.buildMatches()
 
 	if m.YOffset > len(m.lines)-1 {
 		m.GotoBottom()
 	}
 }
 
+// AppendContent appends lines to the pager's content without touching what's
+// already there. If the viewport was at or past the bottom before the
+// append (i.e. following the tail of a streaming query), it auto-scrolls to
+// keep the new rows visible. It returns only the lines that auto-scroll
+// brought into view (none if it didn't scroll) so callers using
+// HighPerformanceRendering can scroll just that slice into view instead of
+// resyncing the whole viewport.
+func (m *ViewportModel) AppendContent(lines []string) (newLines []string) {
+	wasAtBottom := m.AtBottom()
+	oldYOffset := m.YOffset
+
+	m.lines = append(m.lines, lines...)
+	m.wrappedLines = nil
+	m.buildMatches()
+
+	if wasAtBottom {
+		visible := m.GotoBottom()
+		shift := clamp(m.YOffset-oldYOffset, 0, len(visible))
+		newLines = visible[len(visible)-shift:]
+	}
+
+	return newLines
+}
+
+// DeleteTopContent deletes the first n lines of the viewport's content,
+// adjusting YOffset so the remaining content doesn't jump under the cursor.
+// It's meant for trimming the head of a long-running query's results as new
+// rows are appended, without paying the cost of resetting the whole buffer.
+func (m *ViewportModel) DeleteTopContent(n int) (lines []string) {
+	if n > len(m.lines) {
+		n = len(m.lines)
+	}
+
+	// YOffset is expressed in renderedLines() units. When SoftWrap is on,
+	// the n deleted raw lines may have expanded into a different number of
+	// wrapped lines, so measure the shift against those, not n itself.
+	delta := n
+	if m.SoftWrap {
+		delta = len(wrap(m.lines[:n], m.Width))
+	}
+
+	m.lines = m.lines[n:]
+	m.wrappedLines = nil
+	m.buildMatches()
+	m.YOffset = max(0, m.YOffset-delta)
+
+	return m.visibleLines()
+}
+
 // Return the lines that should currently be visible in the viewport.
-func (m ViewportModel) visibleLines() (lines []string) {
-	if len(m.lines) > 0 {
+func (m *ViewportModel) visibleLines() (lines []string) {
+	lines = m.rawVisibleLines()
+
+	if !m.SoftWrap && m.XOffset > 0 {
+		lines = clipLinesLeft(lines, m.XOffset)
+	}
+
+	return lines
+}
+
+// rawVisibleLines returns the currently visible lines before horizontal
+// clipping. View() needs this unclipped form so it can highlight search
+// matches (whose columns are measured against the unclipped line) before
+// clipLinesLeft shifts everything by XOffset.
+func (m *ViewportModel) rawVisibleLines() (lines []string) {
+	all := m.renderedLines()
+	if len(all) > 0 {
 		top := max(0, m.YOffset)
-		bottom := clamp(m.YOffset+m.Height, top, len(m.lines))
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+m.Height, top, len(all))
+		lines = all[top:bottom]
 	}
 	return lines
 }
 
+// clipLinesLeft clips each line to start at xOffset display cells in.
+func clipLinesLeft(lines []string, xOffset int) []string {
+	clipped := make([]string, len(lines))
+	for i, line := range lines {
+		clipped[i] = clipLeft(line, xOffset)
+	}
+	return clipped
+}
+
+// LineLeft scrolls the viewport left by the given number of cells. It's a
+// no-op when SoftWrap is enabled, since wrapped content has nothing to
+// scroll horizontally.
+func (m *ViewportModel) LineLeft(n int) (lines []string) {
+	if m.SoftWrap || n == 0 {
+		return nil
+	}
+
+	m.XOffset = max(0, m.XOffset-n)
+
+	return m.visibleLines()
+}
+
+// LineRight scrolls the viewport right by the given number of cells,
+// clamped so the widest rendered line's right edge doesn't scroll past the
+// viewport. It's a no-op when SoftWrap is enabled.
+func (m *ViewportModel) LineRight(n int) (lines []string) {
+	if m.SoftWrap || n == 0 {
+		return nil
+	}
+
+	m.XOffset = clamp(m.XOffset+n, 0, m.maxXOffset())
+
+	return m.visibleLines()
+}
+
+// GotoLeftmost resets horizontal scroll to the left edge.
+func (m *ViewportModel) GotoLeftmost() (lines []string) {
+	if m.XOffset == 0 {
+		return nil
+	}
+
+	m.XOffset = 0
+
+	return m.visibleLines()
+}
+
+// GotoRightmost scrolls horizontally as far right as the widest rendered
+// line allows.
+func (m *ViewportModel) GotoRightmost() (lines []string) {
+	maxX := m.maxXOffset()
+	if m.XOffset == maxX {
+		return nil
+	}
+
+	m.XOffset = maxX
+
+	return m.visibleLines()
+}
+
+// maxXOffset returns the furthest XOffset can scroll right before the
+// widest rendered line's right edge would scroll past Width.
+func (m *ViewportModel) maxXOffset() int {
+	return max(0, maxLineWidth(m.renderedLines())-m.Width)
+}
+
 // ViewDown moves the view down by the number of lines in the viewport.
 // Basically, "page down".
 func (m *ViewportModel) ViewDown() []string {
@@ -94,9 +292,10 @@ func (m *ViewportModel) ViewDown() []string {
 		return nil
 	}
 
+	lines := m.renderedLines()
 	m.YOffset = min(
-		m.YOffset+m.Height,      // target
-		len(m.lines)-1-m.Height, // fallback
+		m.YOffset+m.Height,    // target
+		len(lines)-1-m.Height, // fallback
 	)
 
 	return m.visibleLines()
@@ -122,15 +321,16 @@ func (m *ViewportModel) HalfViewDown() (lines []string) {
 		return nil
 	}
 
+	all := m.renderedLines()
 	m.YOffset = min(
-		m.YOffset+m.Height/2,    // target
-		len(m.lines)-1-m.Height, // fallback
+		m.YOffset+m.Height/2, // target
+		len(all)-1-m.Height,  // fallback
 	)
 
-	if len(m.lines) > 0 {
+	if len(all) > 0 {
 		top := max(m.YOffset+m.Height/2, 0)
-		bottom := clamp(m.YOffset+m.Height, top, len(m.lines)-1)
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+m.Height, top, len(all)-1)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -147,10 +347,11 @@ func (m *ViewportModel) HalfViewUp() (lines []string) {
 		0,                    // fallback
 	)
 
-	if len(m.lines) > 0 {
+	all := m.renderedLines()
+	if len(all) > 0 {
 		top := max(m.YOffset, 0)
-		bottom := clamp(m.YOffset+m.Height/2, top, len(m.lines)-1)
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+m.Height/2, top, len(all)-1)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -162,21 +363,23 @@ func (m *ViewportModel) LineDown(n int) (lines []string) {
 		return nil
 	}
 
+	all := m.renderedLines()
+
 	// Make sure the number of lines by which we're going to scroll isn't
 	// greater than the number of lines we actually have left before we reach
 	// the bottom.
-	maxDelta := (len(m.lines) - 1) - (m.YOffset + m.Height) // number of lines - viewport bottom edge
+	maxDelta := (len(all) - 1) - (m.YOffset + m.Height) // number of lines - viewport bottom edge
 	n = min(n, maxDelta)
 
 	m.YOffset = min(
-		m.YOffset+n,             // target
-		len(m.lines)-1-m.Height, // fallback
+		m.YOffset+n,         // target
+		len(all)-1-m.Height, // fallback
 	)
 
-	if len(m.lines) > 0 {
+	if len(all) > 0 {
 		top := max(m.YOffset+m.Height-n, 0)
-		bottom := clamp(m.YOffset+m.Height, top, len(m.lines)-1)
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+m.Height, top, len(all)-1)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -195,10 +398,11 @@ func (m *ViewportModel) LineUp(n int) (lines []string) {
 
 	m.YOffset = max(m.YOffset-n, 0)
 
-	if len(m.lines) > 0 {
+	all := m.renderedLines()
+	if len(all) > 0 {
 		top := max(0, m.YOffset)
-		bottom := clamp(m.YOffset+n, top, len(m.lines)-1)
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+n, top, len(all)-1)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -212,10 +416,11 @@ func (m *ViewportModel) GotoTop() (lines []string) {
 
 	m.YOffset = 0
 
-	if len(m.lines) > 0 {
+	all := m.renderedLines()
+	if len(all) > 0 {
 		top := m.YOffset
-		bottom := clamp(m.YOffset+m.Height, top, len(m.lines)-1)
-		lines = m.lines[top:bottom]
+		bottom := clamp(m.YOffset+m.Height, top, len(all)-1)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -223,12 +428,13 @@ func (m *ViewportModel) GotoTop() (lines []string) {
 
 // GotoBottom sets the viewport to the bottom position.
 func (m *ViewportModel) GotoBottom() (lines []string) {
-	m.YOffset = max(len(m.lines)-1-m.Height, 0)
+	all := m.renderedLines()
+	m.YOffset = max(len(all)-1-m.Height, 0)
 
-	if len(m.lines) > 0 {
+	if len(all) > 0 {
 		top := m.YOffset
-		bottom := max(len(m.lines)-1, 0)
-		lines = m.lines[top:bottom]
+		bottom := max(len(all)-1, 0)
+		lines = all[top:bottom]
 	}
 
 	return lines
@@ -242,17 +448,13 @@ func (m *ViewportModel) GotoBottom() (lines []string) {
 //
 // For high performance rendering only.
 func Sync(m ViewportModel) tea.Cmd {
-	if len(m.lines) == 0 {
+	lines := m.visibleLines()
+	if len(lines) == 0 {
 		return nil
 	}
 
-	// TODO: we should probably use m.visibleLines() rather than these two
-	// expressions.
-	top := max(m.YOffset, 0)
-	bottom := clamp(m.YOffset+m.Height, 0, len(m.lines)-1)
-
 	return tea.SyncScrollArea(
-		m.lines[top:bottom],
+		lines,
 		m.YPosition,
 		m.YPosition+m.Height,
 	)
@@ -262,9 +464,8 @@ func Sync(m ViewportModel) tea.Cmd {
 // numer of lines. Use ViewportModel.ViewDown to get the lines that should be rendered.
 // For example:
 //
-//     lines := model.ViewDown(1)
-//     cmd := ViewDown(m, lines)
-//
+//	lines := model.ViewDown(1)
+//	cmd := ViewDown(m, lines)
 func ViewDown(m ViewportModel, lines []string) tea.Cmd {
 	if len(lines) == 0 {
 		return nil
@@ -284,56 +485,100 @@ func ViewUp(m ViewportModel, lines []string) tea.Cmd {
 
 // UPDATE
 
-// Update runs the update loop with default keybindings similar to popular
-// pagers. To define your own keybindings use the methods on ViewportModel (i.e.
-// ViewportModel.LineDown()) and define your own update function.
+// Update runs the update loop with the keybindings defined in m.KeyMap,
+// which defaults to the bindings used by popular pagers. To remap keys,
+// assign a customized KeyMap (see DefaultKeyMap). To define your own update
+// loop entirely, use the methods on ViewportModel (i.e. ViewportModel.LineDown())
+// and define your own update function.
 func (m ViewportModel) Update(msg tea.Msg) (ViewportModel, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		switch {
 		// Down one page
-		case "pgdown", spacebar, "f":
+		case key.Matches(msg, m.KeyMap.PageDown):
 			lines := m.ViewDown()
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		// Up one page
-		case "pgup", "b":
+		case key.Matches(msg, m.KeyMap.PageUp):
 			lines := m.ViewUp()
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
 
 		// Down half page
-		case "d", "ctrl+d":
+		case key.Matches(msg, m.KeyMap.HalfPageDown):
 			lines := m.HalfViewDown()
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		// Up half page
-		case "u", "ctrl+u":
+		case key.Matches(msg, m.KeyMap.HalfPageUp):
 			lines := m.HalfViewUp()
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
 
 		// Down one line
-		case "down", "j":
+		case key.Matches(msg, m.KeyMap.Down):
 			lines := m.LineDown(1)
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		// Up one line
-		case "up", "k":
+		case key.Matches(msg, m.KeyMap.Up):
 			lines := m.LineUp(1)
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
+
+		// Jump to top
+		case key.Matches(msg, m.KeyMap.GotoTop):
+			lines := m.GotoTop()
+			if m.HighPerformanceRendering {
+				cmd = ViewUp(m, lines)
+			}
+
+		// Jump to bottom
+		case key.Matches(msg, m.KeyMap.GotoBottom):
+			lines := m.GotoBottom()
+			if m.HighPerformanceRendering {
+				cmd = ViewDown(m, lines)
+			}
+
+		// Scroll left
+		case key.Matches(msg, m.KeyMap.Left) && !m.SoftWrap:
+			m.LineLeft(1)
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
+
+		// Scroll right
+		case key.Matches(msg, m.KeyMap.Right) && !m.SoftWrap:
+			m.LineRight(1)
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
+
+		// Jump to next search match
+		case key.Matches(msg, m.KeyMap.NextMatch):
+			m.NextMatch()
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
+
+		// Jump to previous search match
+		case key.Matches(msg, m.KeyMap.PrevMatch):
+			m.PrevMatch()
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
 		}
 
 	case tea.MouseMsg:
@@ -367,7 +612,24 @@ func (m ViewportModel) View() string {
 		return strings.Repeat("\n", m.Height-1)
 	}
 
-	lines := m.visibleLines()
+	lines := m.rawVisibleLines()
+
+	// Highlighting assumes match.line indexes raw m.lines directly, which
+	// only holds when SoftWrap is off (see ErrSearchUnsupportedWithSoftWrap
+	// and gotoMatch). SetSearch already refuses to populate m.matches while
+	// SoftWrap is on; this guards the case where SoftWrap is toggled on
+	// after a search was set.
+	if len(m.matches) > 0 && !m.SoftWrap {
+		highlighted := make([]string, len(lines))
+		for i, line := range lines {
+			highlighted[i] = m.highlightLine(m.YOffset+i, line)
+		}
+		lines = highlighted
+	}
+
+	if !m.SoftWrap && m.XOffset > 0 {
+		lines = clipLinesLeft(lines, m.XOffset)
+	}
 
 	// Fill empty space with newlines
 	extraLines := ""
@@ -396,4 +658,4 @@ func max(a, b int) int {
 		return a
 	}
 	return b
-}
\ No newline at end of file
+}
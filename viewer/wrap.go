@@ -0,0 +1,31 @@
+package viewer
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wrap soft-wraps each line in lines to width display cells. Escape sequences
+// already present in the content are kept in place and don't count toward
+// the width, but a style opened before a break is not repeated at the start
+// of the following wrapped line. Lines already narrower than width pass
+// through unchanged. A non-positive width disables wrapping.
+func wrap(lines []string, width int) []string {
+	if width <= 0 {
+		return lines
+	}
+
+	wrapped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line == "" {
+			wrapped = append(wrapped, "")
+			continue
+		}
+		for _, w := range strings.Split(ansi.Hardwrap(line, width, true), "\n") {
+			wrapped = append(wrapped, w)
+		}
+	}
+
+	return wrapped
+}
@@ -0,0 +1,72 @@
+package viewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		width int
+		want  []string
+	}{
+		{
+			name:  "line narrower than width is unchanged",
+			lines: []string{"abc"},
+			width: 10,
+			want:  []string{"abc"},
+		},
+		{
+			name:  "line wider than width splits on cell boundaries",
+			lines: []string{"abcdefghij"},
+			width: 4,
+			want:  []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:  "non-positive width disables wrapping",
+			lines: []string{"abcdefghij"},
+			width: 0,
+			want:  []string{"abcdefghij"},
+		},
+		{
+			name:  "empty line is preserved",
+			lines: []string{""},
+			width: 4,
+			want:  []string{""},
+		},
+		{
+			name:  "multiple lines wrap independently",
+			lines: []string{"ab", "cdefgh"},
+			width: 3,
+			want:  []string{"ab", "cde", "fgh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrap(tt.lines, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrap(%q, %d) = %q, want %q", tt.lines, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestViewDownReachesBottomWithSoftWrap guards against the regression where
+// YOffset was computed in raw-line units while AtBottom/visibleLines read it
+// in wrapped-line units, pinning the viewport short of the true bottom.
+func TestViewDownReachesBottomWithSoftWrap(t *testing.T) {
+	m := ViewportModel{Width: 10, Height: 2, SoftWrap: true}
+	m.SetContent("short\n" + strings.Repeat("a", 100) + "\nshort")
+
+	for i := 0; i < 50 && !m.AtBottom(); i++ {
+		m.ViewDown()
+	}
+
+	if !m.AtBottom() {
+		t.Fatalf("viewport never reached bottom; YOffset=%d wrapped lines=%d", m.YOffset, len(m.renderedLines()))
+	}
+}